@@ -0,0 +1,139 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Keys of the metadata carrying the API key and the authentication token on
+// outgoing requests.
+const (
+	apiKeyKey = "x-api-key"
+	tokenKey  = "authorization"
+)
+
+// AuthInterceptor is a gRPC interceptor that injects the API key and the
+// authentication token of the client into outgoing requests. Both values can
+// be rotated at runtime via SetAPIKey and SetToken, for example by a client
+// that is watching the server for auth changes through WatchAuth.
+type AuthInterceptor struct {
+	mu              sync.RWMutex
+	apiKey          string
+	token           string
+	allowedPrefixes []string
+}
+
+// NewAuthInterceptor creates an instance of AuthInterceptor.
+func NewAuthInterceptor(apiKey, token string) *AuthInterceptor {
+	return &AuthInterceptor{
+		apiKey: apiKey,
+		token:  token,
+	}
+}
+
+// SetAllowedPrefixes updates the set of document key prefixes this client is
+// allowed to attach to. An empty or nil prefixes allows every document key,
+// which is also the default before the server has pushed its first
+// AllowedPrefixesChanged event.
+func (i *AuthInterceptor) SetAllowedPrefixes(prefixes []string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.allowedPrefixes = prefixes
+}
+
+// IsDocumentKeyAllowed reports whether docKey matches one of the allowed
+// prefixes. It always returns true while no prefixes have been set.
+func (i *AuthInterceptor) IsDocumentKeyAllowed(docKey string) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if len(i.allowedPrefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range i.allowedPrefixes {
+		if strings.HasPrefix(docKey, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAPIKey updates the API key used for subsequent requests. It is safe to
+// call concurrently with in-flight requests.
+func (i *AuthInterceptor) SetAPIKey(apiKey string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.apiKey = apiKey
+}
+
+// SetToken updates the authentication token used for subsequent requests. It
+// is safe to call concurrently with in-flight requests.
+func (i *AuthInterceptor) SetToken(token string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.token = token
+}
+
+func (i *AuthInterceptor) outgoingContext(ctx context.Context) context.Context {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if i.apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, apiKeyKey, i.apiKey)
+	}
+	if i.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tokenKey, i.token)
+	}
+	return ctx
+}
+
+// Unary returns a new unary client interceptor that attaches the API key and
+// the authentication token to the outgoing context.
+func (i *AuthInterceptor) Unary() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return invoker(i.outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// Stream returns a new stream client interceptor that attaches the API key
+// and the authentication token to the outgoing context.
+func (i *AuthInterceptor) Stream() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		return streamer(i.outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
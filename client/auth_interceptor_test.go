@@ -0,0 +1,50 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yorkie-team/yorkie/client"
+)
+
+// TestAuthInterceptor_AllowedPrefixes verifies that IsDocumentKeyAllowed is
+// permissive until the server has pushed an AllowedPrefixesChanged event,
+// and enforces the most recently pushed set of prefixes afterward.
+func TestAuthInterceptor_AllowedPrefixes(t *testing.T) {
+	i := client.NewAuthInterceptor("", "")
+
+	// No AllowedPrefixesChanged event has arrived yet: every key is allowed,
+	// matching the client's behavior before this ACL existed.
+	assert.True(t, i.IsDocumentKeyAllowed("tenant-a/doc-1"))
+
+	i.SetAllowedPrefixes([]string{"tenant-a/", "shared/"})
+	assert.True(t, i.IsDocumentKeyAllowed("tenant-a/doc-1"))
+	assert.True(t, i.IsDocumentKeyAllowed("shared/doc-1"))
+	assert.False(t, i.IsDocumentKeyAllowed("tenant-b/doc-1"))
+
+	// A later event narrowing the prefixes further takes effect immediately.
+	i.SetAllowedPrefixes([]string{"shared/"})
+	assert.False(t, i.IsDocumentKeyAllowed("tenant-a/doc-1"))
+	assert.True(t, i.IsDocumentKeyAllowed("shared/doc-1"))
+
+	// Clearing the prefixes reverts to allowing everything.
+	i.SetAllowedPrefixes(nil)
+	assert.True(t, i.IsDocumentKeyAllowed("tenant-a/doc-1"))
+}
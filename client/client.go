@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	gotime "time"
 
 	"github.com/rs/xid"
 	"go.uber.org/zap"
@@ -31,6 +33,7 @@ import (
 	"github.com/yorkie-team/yorkie/api/converter"
 	"github.com/yorkie-team/yorkie/api/types"
 	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
 	"github.com/yorkie-team/yorkie/pkg/document/key"
 	"github.com/yorkie-team/yorkie/pkg/document/time"
 )
@@ -54,6 +57,13 @@ var (
 	// ErrUnsupportedWatchResponseType occurs when the given WatchResponseType
 	// is not supported.
 	ErrUnsupportedWatchResponseType = errors.New("unsupported watch response type")
+
+	// ErrDocumentKeyNotAllowed occurs when the client tries to attach a
+	// document whose key does not match any of the prefixes most recently
+	// pushed to it by WatchAuth. It is only enforced on Attach: a document
+	// already attached before its prefix was revoked must still be
+	// detachable, or it could never be released again.
+	ErrDocumentKeyNotAllowed = errors.New("document key is not allowed")
 )
 
 // Attachment represents the document attached and peers.
@@ -66,18 +76,29 @@ type Attachment struct {
 // It has documents and sends changes of the document in local
 // to the server to synchronize with other replicas in remote.
 type Client struct {
-	conn        *grpc.ClientConn
-	client      api.YorkieClient
-	dialOptions []grpc.DialOption
-	logger      *zap.Logger
-
-	id           *time.ActorID
-	key          string
-	presenceInfo types.PresenceInfo
-	status       status
-	attachments  map[string]*Attachment
+	conn            *grpc.ClientConn
+	client          api.YorkieClient
+	dialOptions     []grpc.DialOption
+	authInterceptor *AuthInterceptor
+	logger          *zap.Logger
+
+	id                 *time.ActorID
+	key                string
+	presenceInfo       types.PresenceInfo
+	status             status
+	attachments        map[string]*Attachment
+	storage            Storage
+	maxInFlightSyncs   int
+	supportsSyncStream bool
+	lease              presenceLease
+
+	logSinksMu sync.RWMutex
+	logSinks   []LogSink
 }
 
+// defaultMaxInFlightSyncs is used when Options.MaxInFlightSyncs is not set.
+const defaultMaxInFlightSyncs = 8
+
 // WatchResponseType is type of watch response.
 type WatchResponseType string
 
@@ -137,15 +158,37 @@ func New(opts ...Option) (*Client, error) {
 		logger = l
 	}
 
-	return &Client{
-		dialOptions: dialOptions,
-		logger:      logger,
+	storage := options.Storage
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+
+	maxInFlightSyncs := options.MaxInFlightSyncs
+	if maxInFlightSyncs <= 0 {
+		maxInFlightSyncs = defaultMaxInFlightSyncs
+	}
+
+	presenceTTL := options.PresenceTTL
+	if presenceTTL <= 0 {
+		presenceTTL = defaultPresenceTTL
+	}
 
-		key:          k,
-		presenceInfo: types.PresenceInfo{Presence: presence},
-		status:       deactivated,
-		attachments:  make(map[string]*Attachment),
-	}, nil
+	cli := &Client{
+		dialOptions:     dialOptions,
+		authInterceptor: authInterceptor,
+		logger:          logger,
+
+		key:              k,
+		presenceInfo:     types.PresenceInfo{Presence: presence},
+		status:           deactivated,
+		attachments:      make(map[string]*Attachment),
+		storage:          storage,
+		maxInFlightSyncs: maxInFlightSyncs,
+		lease:            presenceLease{ttl: presenceTTL},
+	}
+	cli.logSinks = []LogSink{NewZapLogSink(logger)}
+
+	return cli, nil
 }
 
 // Dial creates an instance of Client and dials the given rpcAddr.
@@ -186,15 +229,26 @@ func (c *Client) Close() error {
 
 // Activate activates this client. That is, it registers itself to the server
 // and receives a unique ID from the server. The given ID is used to distinguish
-// different clients.
+// different clients. If this client key was activated and persisted before,
+// the previously stored actor ID is offered to the server as a reuse
+// request; the server alone decides whether to honor it, so the ID this
+// client ends up using is always the one the server confirms in its
+// response, never a locally-overridden value.
 func (c *Client) Activate(ctx context.Context) error {
 	if c.status == activated {
 		return nil
 	}
 
-	response, err := c.client.ActivateClient(ctx, &api.ActivateClientRequest{
+	req := &api.ActivateClientRequest{
 		ClientKey: c.key,
-	})
+	}
+	if storedID, ok, err := c.storage.LoadActorID(ctx, c.key); err != nil {
+		return err
+	} else if ok {
+		req.PreviousClientId = storedID.Bytes()
+	}
+
+	response, err := c.client.ActivateClient(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -204,10 +258,15 @@ func (c *Client) Activate(ctx context.Context) error {
 		return err
 	}
 
+	if err := c.storage.SaveActorID(ctx, c.key, clientID); err != nil {
+		return err
+	}
+
 	c.status = activated
 	c.id = clientID
+	c.supportsSyncStream = response.SyncStreamSupported
 
-	return nil
+	return c.requestLease(ctx)
 }
 
 // Deactivate deactivates this client.
@@ -223,6 +282,7 @@ func (c *Client) Deactivate(ctx context.Context) error {
 		return err
 	}
 
+	c.stopHeartbeat()
 	c.status = deactivated
 
 	return nil
@@ -230,13 +290,35 @@ func (c *Client) Deactivate(ctx context.Context) error {
 
 // Attach attaches the given document to this client. It tells the server that
 // this client will synchronize the given document.
-func (c *Client) Attach(ctx context.Context, doc *document.Document) error {
+func (c *Client) Attach(ctx context.Context, doc *document.Document) (err error) {
 	if c.status != activated {
 		return ErrClientNotActivated
 	}
+	if !c.authInterceptor.IsDocumentKeyAllowed(doc.Key().String()) {
+		return ErrDocumentKeyNotAllowed
+	}
+
+	start := gotime.Now()
+	var serverSeq int64
+	defer func() {
+		c.publish(ctx, Entry{
+			Type:      EntryAttach,
+			ClientID:  c.id.String(),
+			DocKey:    doc.Key().String(),
+			ServerSeq: serverSeq,
+			Latency:   gotime.Since(start),
+			Err:       err,
+		})
+	}()
 
 	doc.SetActor(c.id)
 
+	cp, err := c.storage.LoadCheckpoint(ctx, c.key, doc.Key())
+	if err != nil {
+		return err
+	}
+	doc.SetCheckpoint(cp)
+
 	pbChangePack, err := converter.ToChangePack(doc.CreateChangePack())
 	if err != nil {
 		return err
@@ -254,6 +336,7 @@ func (c *Client) Attach(ctx context.Context, doc *document.Document) error {
 	if err != nil {
 		return err
 	}
+	serverSeq = pack.Checkpoint.ServerSeq
 
 	if err := doc.ApplyChangePack(pack); err != nil {
 		return err
@@ -268,12 +351,13 @@ func (c *Client) Attach(ctx context.Context, doc *document.Document) error {
 	}
 
 	doc.SetStatus(document.Attached)
-	c.attachments[doc.Key().String()] = &Attachment{
+	attachment := &Attachment{
 		doc:   doc,
 		peers: make(map[string]types.PresenceInfo),
 	}
+	c.attachments[doc.Key().String()] = attachment
 
-	return nil
+	return c.replayPendingChangePacks(ctx, doc.Key(), attachment)
 }
 
 // Detach detaches the given document from this client. It tells the
@@ -282,7 +366,7 @@ func (c *Client) Attach(ctx context.Context, doc *document.Document) error {
 // To collect garbage things like CRDT tombstones left on the document, all the
 // changes should be applied to other replicas before GC time. For this, if the
 // document is no longer used by this client, it should be detached.
-func (c *Client) Detach(ctx context.Context, doc *document.Document) error {
+func (c *Client) Detach(ctx context.Context, doc *document.Document) (err error) {
 	if c.status != activated {
 		return ErrClientNotActivated
 	}
@@ -291,6 +375,19 @@ func (c *Client) Detach(ctx context.Context, doc *document.Document) error {
 		return ErrDocumentNotAttached
 	}
 
+	start := gotime.Now()
+	var serverSeq int64
+	defer func() {
+		c.publish(ctx, Entry{
+			Type:      EntryDetach,
+			ClientID:  c.id.String(),
+			DocKey:    doc.Key().String(),
+			ServerSeq: serverSeq,
+			Latency:   gotime.Since(start),
+			Err:       err,
+		})
+	}()
+
 	pbChangePack, err := converter.ToChangePack(doc.CreateChangePack())
 	if err != nil {
 		return err
@@ -308,6 +405,7 @@ func (c *Client) Detach(ctx context.Context, doc *document.Document) error {
 	if err != nil {
 		return err
 	}
+	serverSeq = pack.Checkpoint.ServerSeq
 
 	if err := doc.ApplyChangePack(pack); err != nil {
 		return err
@@ -321,21 +419,268 @@ func (c *Client) Detach(ctx context.Context, doc *document.Document) error {
 
 // Sync pushes local changes of the attached documents to the server and
 // receives changes of the remote replica from the server then apply them to
-// local documents.
+// local documents. When multiple documents are given, they are synced
+// through SyncAsync so that one slow document doesn't stall the others; the
+// first error observed from any of them is returned.
 func (c *Client) Sync(ctx context.Context, keys ...key.Key) error {
+	for result := range c.SyncAsync(ctx, keys...) {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+
+	return nil
+}
+
+// SyncResult is the outcome of syncing a single document through SyncAsync.
+type SyncResult struct {
+	// Key is the key of the document this result is about.
+	Key key.Key
+
+	// Err is the error that occurred while syncing the document, if any.
+	Err error
+}
+
+// SyncAsync pushes local changes of the given documents (or all attached
+// documents if none are given) to the server and returns a channel carrying
+// one SyncResult per document as it completes, so that callers can observe
+// per-document completion instead of waiting for every document to finish.
+//
+// When the server advertises support for it during Activate, documents are
+// synced over a single bidirectional PushPullStream, bounded by
+// WithMaxInFlightSyncs so that a client with many attached documents doesn't
+// overwhelm the server with one frame per document at once. Otherwise, it
+// falls back to issuing the unary PushPull RPC for each document, with the
+// same bound on concurrency.
+//
+// The PushPullStream RPC and the ActivateClientResponse.SyncStreamSupported
+// flag that gates it are server/proto-side work that lives in the main
+// repository, outside this client-only tree; syncStream is written to the
+// shape that RPC is expected to have, but cannot itself make the server
+// support it.
+func (c *Client) SyncAsync(ctx context.Context, keys ...key.Key) <-chan SyncResult {
 	if len(keys) == 0 {
 		for _, attachment := range c.attachments {
 			keys = append(keys, attachment.doc.Key())
 		}
 	}
 
+	rch := make(chan SyncResult, len(keys))
+	if len(keys) == 0 {
+		close(rch)
+		return rch
+	}
+
+	if c.supportsSyncStream {
+		go func() {
+			defer close(rch)
+			c.syncStream(ctx, keys, rch)
+		}()
+		return rch
+	}
+
+	go func() {
+		defer close(rch)
+
+		sem := make(chan struct{}, c.maxInFlightSyncs)
+		var wg sync.WaitGroup
+		for _, k := range keys {
+			k := k
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				rch <- SyncResult{Key: k, Err: c.sync(ctx, k)}
+			}()
+		}
+		wg.Wait()
+	}()
+
+	return rch
+}
+
+// inFlightSync tracks the state of a document sent over a PushPullStream
+// whose response has not yet arrived, so the receive goroutine can find the
+// change pack to delete from storage and the start time to compute latency
+// from once the response for that document comes back.
+type inFlightSync struct {
+	start gotime.Time
+	pack  *change.Pack
+}
+
+// syncStream syncs the given documents over a single bidirectional
+// PushPullStream, sending at most maxInFlightSyncs frames before their
+// responses are acknowledged and emitting a SyncResult per document as its
+// response arrives.
+func (c *Client) syncStream(ctx context.Context, keys []key.Key, rch chan<- SyncResult) {
+	stream, err := c.client.PushPullStream(ctx)
+	if err != nil {
+		for _, k := range keys {
+			rch <- SyncResult{Key: k, Err: err}
+		}
+		return
+	}
+
+	recvDone := make(chan struct{})
+	sem := make(chan struct{}, c.maxInFlightSyncs)
+
+	var inFlightMu sync.Mutex
+	inFlight := make(map[string]*inFlightSync)
+
+	// sent carries one value per document frame actually written to the
+	// stream, and is closed once the send loop below is done. The receive
+	// goroutine must call Recv() exactly once per frame sent, not once per
+	// key in keys: a key can fail locally (not attached, replay error,
+	// marshal error) before a frame for it is ever sent, and the server
+	// never produces a response for a frame it didn't receive.
+	sent := make(chan struct{}, len(keys))
+
+	go func() {
+		defer close(recvDone)
+		for range sent {
+			pbResp, err := stream.Recv()
+			<-sem
+			if err != nil {
+				// No frame gets a response once Recv fails: free the window
+				// for the rest, then fail every document still in flight.
+				for range sent {
+					<-sem
+				}
+				for docKey := range inFlight {
+					c.publish(ctx, Entry{Type: EntrySync, ClientID: c.id.String(), DocKey: docKey, Err: err})
+					rch <- SyncResult{Key: key.Key(docKey), Err: err}
+				}
+				return
+			}
+
+			k, attachment, err := c.attachmentByDocID(pbResp.DocumentId)
+			if err != nil {
+				c.publish(ctx, Entry{Type: EntrySync, ClientID: c.id.String(), Err: err})
+				rch <- SyncResult{Err: err}
+				continue
+			}
+
+			inFlightMu.Lock()
+			state := inFlight[k.String()]
+			delete(inFlight, k.String())
+			inFlightMu.Unlock()
+
+			var serverSeq int64
+			syncErr := func() error {
+				pack, err := converter.FromChangePack(pbResp.ChangePack)
+				if err != nil {
+					return err
+				}
+
+				if state != nil {
+					if err := c.storage.DeletePack(ctx, c.key, k, state.pack.Checkpoint.ClientSeq); err != nil {
+						return err
+					}
+				}
+
+				if err := attachment.doc.ApplyChangePack(pack); err != nil {
+					return err
+				}
+				serverSeq = pack.Checkpoint.ServerSeq
+
+				return c.storage.SaveCheckpoint(ctx, c.key, k, attachment.doc.Checkpoint())
+			}()
+
+			var latency gotime.Duration
+			if state != nil {
+				latency = gotime.Since(state.start)
+			}
+			c.publish(ctx, Entry{
+				Type:      EntrySync,
+				ClientID:  c.id.String(),
+				DocKey:    k.String(),
+				ServerSeq: serverSeq,
+				Latency:   latency,
+				Err:       syncErr,
+			})
+
+			rch <- SyncResult{Key: k, Err: syncErr}
+		}
+	}()
+
+	fail := func(k key.Key, err error) {
+		c.publish(ctx, Entry{Type: EntrySync, ClientID: c.id.String(), DocKey: k.String(), Err: err})
+		rch <- SyncResult{Key: k, Err: err}
+	}
+
 	for _, k := range keys {
-		if err := c.sync(ctx, k); err != nil {
-			return err
+		localPack, pbChangePack, err := c.prepareSyncFrame(ctx, k)
+		if err != nil {
+			fail(k, err)
+			continue
 		}
+
+		sem <- struct{}{}
+		inFlightMu.Lock()
+		inFlight[k.String()] = &inFlightSync{start: gotime.Now(), pack: localPack}
+		inFlightMu.Unlock()
+
+		if err := stream.Send(&api.PushPullStreamRequest{
+			ClientId:   c.id.Bytes(),
+			ChangePack: pbChangePack,
+		}); err != nil {
+			inFlightMu.Lock()
+			delete(inFlight, k.String())
+			inFlightMu.Unlock()
+			<-sem
+			fail(k, err)
+			continue
+		}
+
+		sent <- struct{}{}
 	}
+	close(sent)
 
-	return nil
+	if err := stream.CloseSend(); err != nil {
+		c.logger.Error("failed to close sync stream", zap.Error(err))
+	}
+
+	<-recvDone
+}
+
+// prepareSyncFrame builds the local change pack and its wire representation
+// for the given document key, replaying any pending change packs left over
+// from a previous failed send first. It fails fast, before syncStream spends
+// a stream frame on k, if the document isn't attached or any of this local
+// work fails.
+func (c *Client) prepareSyncFrame(ctx context.Context, k key.Key) (*change.Pack, *api.ChangePack, error) {
+	attachment, ok := c.attachments[k.String()]
+	if !ok {
+		return nil, nil, ErrDocumentNotAttached
+	}
+
+	if err := c.replayPendingChangePacks(ctx, k, attachment); err != nil {
+		return nil, nil, err
+	}
+
+	localPack := attachment.doc.CreateChangePack()
+	if err := c.storage.SaveChangePack(ctx, c.key, k, localPack); err != nil {
+		return nil, nil, err
+	}
+
+	pbChangePack, err := converter.ToChangePack(localPack)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return localPack, pbChangePack, nil
+}
+
+// attachmentByDocID looks up the document key and attachment for the given
+// document ID as reported by the server in a PushPullStream response.
+func (c *Client) attachmentByDocID(docID string) (key.Key, *Attachment, error) {
+	for _, attachment := range c.attachments {
+		if attachment.doc.Key().String() == docID {
+			return attachment.doc.Key(), attachment, nil
+		}
+	}
+	return "", nil, ErrDocumentNotAttached
 }
 
 // Watch subscribes to events on a given document.
@@ -352,6 +697,16 @@ func (c *Client) Watch(
 		keys = append(keys, doc.Key())
 	}
 
+	// Re-establish the presence lease on every (re)connect, including the
+	// first one, so that a lease that expired during a network blip is
+	// replaced before the server starts broadcasting this client as watched
+	// again.
+	watchStart := gotime.Now()
+	if err := c.requestLease(ctx); err != nil {
+		c.publish(ctx, Entry{Type: EntryWatchReconnected, ClientID: c.id.String(), Latency: gotime.Since(watchStart), Err: err})
+		return nil, err
+	}
+
 	rch := make(chan WatchResponse)
 	stream, err := c.client.WatchDocuments(ctx, &api.WatchDocumentsRequest{
 		Client: converter.ToClient(types.Client{
@@ -361,6 +716,7 @@ func (c *Client) Watch(
 		DocumentKeys: converter.ToDocumentKeys(keys),
 	})
 	if err != nil {
+		c.publish(ctx, Entry{Type: EntryWatchReconnected, ClientID: c.id.String(), Latency: gotime.Since(watchStart), Err: err})
 		return nil, err
 	}
 
@@ -373,7 +729,12 @@ func (c *Client) Watch(
 					return nil, err
 				}
 
+				// Reconcile against the snapshot rather than merging into
+				// it: a peer that crashed while this client was
+				// disconnected would otherwise linger in the local peer
+				// map forever.
 				attachment := c.attachments[docID]
+				attachment.peers = make(map[string]types.PresenceInfo, len(clients))
 				for _, cli := range clients {
 					attachment.peers[cli.ID.String()] = cli.PresenceInfo
 				}
@@ -421,16 +782,20 @@ func (c *Client) Watch(
 
 	pbResp, err := stream.Recv()
 	if err != nil {
+		c.publish(ctx, Entry{Type: EntryWatchReconnected, ClientID: c.id.String(), Latency: gotime.Since(watchStart), Err: err})
 		return nil, err
 	}
 	if _, err := handleResponse(pbResp); err != nil {
+		c.publish(ctx, Entry{Type: EntryWatchReconnected, ClientID: c.id.String(), Latency: gotime.Since(watchStart), Err: err})
 		return nil, err
 	}
+	c.publish(ctx, Entry{Type: EntryWatchReconnected, ClientID: c.id.String(), Latency: gotime.Since(watchStart)})
 
 	go func() {
 		for {
 			pbResp, err := stream.Recv()
 			if err != nil {
+				c.publish(context.Background(), Entry{Type: EntryWatchReconnected, ClientID: c.id.String(), Err: err})
 				rch <- WatchResponse{Err: err}
 				close(rch)
 				return
@@ -448,12 +813,116 @@ func (c *Client) Watch(
 	return rch, nil
 }
 
+// AuthEventType is type of auth event from WatchAuth.
+type AuthEventType string
+
+// The values below are types of AuthEventType.
+const (
+	// APIKeyRotated is fired when the API key of the project was rotated.
+	APIKeyRotated AuthEventType = "api-key-rotated"
+
+	// TokenRotated is fired when the authentication token of the client was
+	// rotated.
+	TokenRotated AuthEventType = "token-rotated"
+
+	// AllowedPrefixesChanged is fired when the set of document key prefixes
+	// this client is allowed to attach to has changed.
+	AllowedPrefixesChanged AuthEventType = "allowed-prefixes-changed"
+)
+
+// AuthEvent is a structure representing a change pushed by WatchAuth.
+type AuthEvent struct {
+	Type            AuthEventType
+	APIKey          string
+	Token           string
+	AllowedPrefixes []string
+	Err             error
+}
+
+// WatchAuth subscribes to changes in the API key, the authentication token
+// and the allowed document key prefixes of this client's project, without
+// polling. The server pushes an initial snapshot followed by incremental
+// updates; each update is applied to this client's AuthInterceptor so that
+// rotating a key or tightening an ACL takes effect immediately, without
+// having to reconnect already attached documents.
+//
+// If the context "ctx" is canceled or timed out, the returned channel is
+// closed, and the last "AuthEvent" from the closed channel carries the
+// error that caused the stream to end.
+//
+// WatchAuth is a client for a streaming RPC, and an update is only ever
+// pushed if something on the server actually calls it: the WatchAuth
+// service method, the project-level key/token rotation that produces
+// APIKeyRotated/TokenRotated, and the ACL change that produces
+// AllowedPrefixesChanged all live in the api/proto and server packages of
+// the main repository, outside this client-only tree, and must ship
+// alongside this client before the feature does anything end to end.
+func (c *Client) WatchAuth(ctx context.Context) (<-chan AuthEvent, error) {
+	stream, err := c.client.WatchAuth(ctx, &api.WatchAuthRequest{
+		ClientId: c.id.Bytes(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	handleResponse := func(pbResp *api.WatchAuthResponse) AuthEvent {
+		event := AuthEvent{
+			Type:            AuthEventType(pbResp.Type),
+			APIKey:          pbResp.ApiKey,
+			Token:           pbResp.Token,
+			AllowedPrefixes: pbResp.AllowedPrefixes,
+		}
+
+		switch event.Type {
+		case APIKeyRotated:
+			c.authInterceptor.SetAPIKey(event.APIKey)
+		case TokenRotated:
+			c.authInterceptor.SetToken(event.Token)
+		case AllowedPrefixesChanged:
+			c.authInterceptor.SetAllowedPrefixes(event.AllowedPrefixes)
+		}
+
+		return event
+	}
+
+	pbResp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	handleResponse(pbResp)
+
+	rch := make(chan AuthEvent)
+	go func() {
+		for {
+			pbResp, err := stream.Recv()
+			if err != nil {
+				rch <- AuthEvent{Err: err}
+				close(rch)
+				return
+			}
+			rch <- handleResponse(pbResp)
+		}
+	}()
+
+	return rch, nil
+}
+
 // UpdatePresence updates the presence of this client.
-func (c *Client) UpdatePresence(ctx context.Context, k, v string) error {
+func (c *Client) UpdatePresence(ctx context.Context, k, v string) (err error) {
 	if c.status != activated {
 		return ErrClientNotActivated
 	}
 
+	start := gotime.Now()
+	defer func() {
+		c.publish(ctx, Entry{
+			Type:     EntryPresenceUpdated,
+			ClientID: c.id.String(),
+			Latency:  gotime.Since(start),
+			Err:      err,
+		})
+	}()
+
 	c.presenceInfo.Presence[k] = v
 	c.presenceInfo.Clock++
 
@@ -470,12 +939,17 @@ func (c *Client) UpdatePresence(ctx context.Context, k, v string) error {
 	// because grpc-web can't handle Bi-Directional streaming for now.
 	// After grpc-web supports bi-directional streaming, we can remove the
 	// following.
+	if err := c.storage.SavePresence(ctx, c.key, c.presenceInfo); err != nil {
+		return err
+	}
+
 	if _, err := c.client.UpdatePresence(ctx, &api.UpdatePresenceRequest{
 		Client: converter.ToClient(types.Client{
 			ID:           c.id,
 			PresenceInfo: c.presenceInfo,
 		}),
 		DocumentKeys: converter.ToDocumentKeys(keys),
+		LeaseId:      c.LeaseID(),
 	}); err != nil {
 		return err
 	}
@@ -555,7 +1029,7 @@ func (c *Client) IsActive() bool {
 	return c.status == activated
 }
 
-func (c *Client) sync(ctx context.Context, key key.Key) error {
+func (c *Client) sync(ctx context.Context, key key.Key) (err error) {
 	if c.status != activated {
 		return ErrClientNotActivated
 	}
@@ -565,7 +1039,29 @@ func (c *Client) sync(ctx context.Context, key key.Key) error {
 		return ErrDocumentNotAttached
 	}
 
-	pbChangePack, err := converter.ToChangePack(attachment.doc.CreateChangePack())
+	start := gotime.Now()
+	var serverSeq int64
+	defer func() {
+		c.publish(ctx, Entry{
+			Type:      EntrySync,
+			ClientID:  c.id.String(),
+			DocKey:    key.String(),
+			ServerSeq: serverSeq,
+			Latency:   gotime.Since(start),
+			Err:       err,
+		})
+	}()
+
+	if err := c.replayPendingChangePacks(ctx, key, attachment); err != nil {
+		return err
+	}
+
+	localPack := attachment.doc.CreateChangePack()
+	if err := c.storage.SaveChangePack(ctx, c.key, key, localPack); err != nil {
+		return err
+	}
+
+	pbChangePack, err := converter.ToChangePack(localPack)
 	if err != nil {
 		return err
 	}
@@ -579,15 +1075,67 @@ func (c *Client) sync(ctx context.Context, key key.Key) error {
 		return err
 	}
 
+	if err := c.storage.DeletePack(ctx, c.key, key, localPack.Checkpoint.ClientSeq); err != nil {
+		return err
+	}
+
 	pack, err := converter.FromChangePack(res.ChangePack)
 	if err != nil {
 		return err
 	}
+	serverSeq = pack.Checkpoint.ServerSeq
 
 	if err := attachment.doc.ApplyChangePack(pack); err != nil {
 		c.logger.Error("failed to apply change pack", zap.Error(err))
 		return err
 	}
 
+	if err := c.storage.SaveCheckpoint(ctx, c.key, key, attachment.doc.Checkpoint()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// replayPendingChangePacks resends any change packs that were saved to
+// storage but never acknowledged by the server, for example because the
+// process crashed or lost connectivity after persisting them but before
+// PushPull returned. It must run before any new local changes are sent so
+// that the server observes them in the order they were originally made.
+func (c *Client) replayPendingChangePacks(ctx context.Context, k key.Key, attachment *Attachment) error {
+	pending, err := c.storage.LoadPendingChangePacks(ctx, c.key, k)
+	if err != nil {
+		return err
+	}
+
+	for _, localPack := range pending {
+		pbChangePack, err := converter.ToChangePack(localPack)
+		if err != nil {
+			return err
+		}
+
+		res, err := c.client.PushPull(ctx, &api.PushPullRequest{
+			ClientId:   c.id.Bytes(),
+			ChangePack: pbChangePack,
+		})
+		if err != nil {
+			c.logger.Error("failed to replay pending change pack", zap.Error(err))
+			return err
+		}
+
+		if err := c.storage.DeletePack(ctx, c.key, k, localPack.Checkpoint.ClientSeq); err != nil {
+			return err
+		}
+
+		pack, err := converter.FromChangePack(res.ChangePack)
+		if err != nil {
+			return err
+		}
+
+		if err := attachment.doc.ApplyChangePack(pack); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,218 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+	gotime "time"
+
+	"go.uber.org/zap"
+)
+
+// EntryType is the type of event carried by an Entry.
+type EntryType string
+
+// The values below are types of EntryType.
+const (
+	// EntryAttach is emitted after Attach completes, successfully or not.
+	EntryAttach EntryType = "attach"
+
+	// EntryDetach is emitted after Detach completes, successfully or not.
+	EntryDetach EntryType = "detach"
+
+	// EntrySync is emitted after a single document finishes syncing,
+	// successfully or not.
+	EntrySync EntryType = "sync"
+
+	// EntryPresenceUpdated is emitted after UpdatePresence completes,
+	// successfully or not.
+	EntryPresenceUpdated EntryType = "presence-updated"
+
+	// EntryWatchReconnected is emitted whenever Watch (re)establishes its
+	// stream to the server, and again if that stream later drops.
+	EntryWatchReconnected EntryType = "watch-reconnected"
+)
+
+// Entry is a single structured audit event emitted by a Client.
+type Entry struct {
+	// Type identifies what kind of event this Entry describes.
+	Type EntryType
+
+	// ClientID is the ID of the client that produced this Entry. It may be
+	// empty for events that occur before the client is activated.
+	ClientID string
+
+	// DocKey is the key of the document this Entry is about, if any.
+	DocKey string
+
+	// ServerSeq is the server sequence of the document's checkpoint after
+	// the event, if known.
+	ServerSeq int64
+
+	// Latency is how long the operation described by this Entry took.
+	Latency gotime.Duration
+
+	// Err is the error the operation failed with, or nil on success.
+	Err error
+}
+
+// LogSink receives the structured Entry events emitted by a Client so they
+// can be streamed to external systems such as stdout, a file, Kafka or an
+// HTTP audit endpoint.
+type LogSink interface {
+	// Publish delivers the given Entry to the sink.
+	Publish(ctx context.Context, entry Entry) error
+}
+
+// zapLogSink is the default LogSink installed on every Client. It mirrors
+// the logging this package produced before LogSink was introduced: entries
+// carrying an error (from any operation, not only sync) at error level,
+// everything else at debug level.
+type zapLogSink struct {
+	logger *zap.Logger
+}
+
+// NewZapLogSink creates a LogSink that writes entries to the given zap
+// Logger.
+func NewZapLogSink(logger *zap.Logger) LogSink {
+	return &zapLogSink{logger: logger}
+}
+
+func (s *zapLogSink) Publish(_ context.Context, entry Entry) error {
+	fields := []zap.Field{
+		zap.String("type", string(entry.Type)),
+		zap.String("client_id", entry.ClientID),
+		zap.Duration("latency", entry.Latency),
+	}
+	if entry.DocKey != "" {
+		fields = append(fields, zap.String("doc_key", entry.DocKey))
+	}
+	if entry.ServerSeq != 0 {
+		fields = append(fields, zap.Int64("server_seq", entry.ServerSeq))
+	}
+
+	if entry.Err != nil {
+		s.logger.Error("client event failed", append(fields, zap.Error(entry.Err))...)
+		return nil
+	}
+
+	if s.logger.Core().Enabled(zap.DebugLevel) {
+		s.logger.Debug("client event", fields...)
+	}
+	return nil
+}
+
+// BufferedLogSink wraps another LogSink with a bounded, asynchronously
+// drained queue so that a slow or unavailable downstream sink (a Kafka
+// broker, an HTTP audit endpoint) never blocks the sync path. Entries are
+// dropped when the queue is full rather than applying backpressure.
+type BufferedLogSink struct {
+	next    LogSink
+	entries chan Entry
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewBufferedLogSink creates a BufferedLogSink that forwards to next,
+// buffering up to size entries before starting to drop them.
+func NewBufferedLogSink(next LogSink, size int) *BufferedLogSink {
+	if size <= 0 {
+		size = 256
+	}
+
+	s := &BufferedLogSink{
+		next:    next,
+		entries: make(chan Entry, size),
+		done:    make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+func (s *BufferedLogSink) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case entry := <-s.entries:
+			// Errors from the downstream sink are intentionally swallowed:
+			// there is no sync path left to propagate them to by the time
+			// we're draining the queue asynchronously.
+			_ = s.next.Publish(context.Background(), entry)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Publish implements LogSink. It never blocks: if the queue is full, the
+// entry is dropped.
+func (s *BufferedLogSink) Publish(_ context.Context, entry Entry) error {
+	select {
+	case s.entries <- entry:
+	default:
+	}
+	return nil
+}
+
+// Close stops the background drain goroutine. Entries still queued when
+// Close is called are discarded.
+func (s *BufferedLogSink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	return nil
+}
+
+// Subscribe registers sink to receive every Entry this client publishes and
+// returns a function that unsubscribes it.
+func (c *Client) Subscribe(sink LogSink) func() {
+	c.logSinksMu.Lock()
+	defer c.logSinksMu.Unlock()
+
+	c.logSinks = append(c.logSinks, sink)
+
+	return func() {
+		c.logSinksMu.Lock()
+		defer c.logSinksMu.Unlock()
+
+		for i, s := range c.logSinks {
+			if s == sink {
+				c.logSinks = append(c.logSinks[:i], c.logSinks[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// publish fans the given Entry out to every LogSink registered on this
+// client, including the default one installed in New.
+func (c *Client) publish(ctx context.Context, entry Entry) {
+	c.logSinksMu.RLock()
+	sinks := make([]LogSink, len(c.logSinks))
+	copy(sinks, c.logSinks)
+	c.logSinksMu.RUnlock()
+
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, entry); err != nil {
+			c.logger.Warn("log sink failed to publish entry", zap.Error(err))
+		}
+	}
+}
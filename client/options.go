@@ -0,0 +1,140 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yorkie-team/yorkie/api/types"
+)
+
+// Options configures how we set up the client.
+type Options struct {
+	// Key is the client key. If not set, a random key is generated.
+	Key string
+
+	// APIKey is the API key of the project used to identify the project
+	// from the server.
+	APIKey string
+
+	// Token is the authentication token of this client used to identify
+	// the user from the server.
+	Token string
+
+	// CertFile is the path to the certificate file to use when connecting
+	// to the server over TLS.
+	CertFile string
+
+	// ServerNameOverride overrides the server name used to verify the
+	// hostname on the returned certificates from the server.
+	ServerNameOverride string
+
+	// Presence is the initial presence of this client.
+	Presence types.Presence
+
+	// Logger is the Logger of this client. If not set, a default zap
+	// production logger is created.
+	Logger *zap.Logger
+
+	// Storage is the durable storage driver used to persist change packs,
+	// checkpoints and presence across restarts. If not set, an in-memory
+	// driver is used and no state survives a restart.
+	Storage Storage
+
+	// MaxInFlightSyncs is the maximum number of documents that can be
+	// synced concurrently over the PushPullStream when a Sync call spans
+	// multiple documents. If not set, defaultMaxInFlightSyncs is used.
+	MaxInFlightSyncs int
+
+	// PresenceTTL is the TTL requested for the presence lease granted by
+	// the server on Activate. If not set, defaultPresenceTTL is used.
+	PresenceTTL time.Duration
+}
+
+// Option configures Options.
+type Option func(*Options)
+
+// WithKey configures the key of the client.
+func WithKey(key string) Option {
+	return func(o *Options) {
+		o.Key = key
+	}
+}
+
+// WithAPIKey configures the API key of the client.
+func WithAPIKey(apiKey string) Option {
+	return func(o *Options) {
+		o.APIKey = apiKey
+	}
+}
+
+// WithToken configures the authentication token of the client.
+func WithToken(token string) Option {
+	return func(o *Options) {
+		o.Token = token
+	}
+}
+
+// WithCertFile configures the certificate file to dial the server over TLS.
+func WithCertFile(certFile, serverNameOverride string) Option {
+	return func(o *Options) {
+		o.CertFile = certFile
+		o.ServerNameOverride = serverNameOverride
+	}
+}
+
+// WithPresence configures the initial presence of the client.
+func WithPresence(presence types.Presence) Option {
+	return func(o *Options) {
+		o.Presence = presence
+	}
+}
+
+// WithLogger configures the Logger of the client.
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// WithStorage configures the durable storage driver of the client. Use this
+// to persist change packs, checkpoints and presence across restarts, for
+// example with NewMemoryStorage (the default), NewEtcdStorage, or a driver
+// looked up by name via Open.
+func WithStorage(storage Storage) Option {
+	return func(o *Options) {
+		o.Storage = storage
+	}
+}
+
+// WithMaxInFlightSyncs configures the maximum number of documents that can
+// be synced concurrently over the PushPullStream.
+func WithMaxInFlightSyncs(n int) Option {
+	return func(o *Options) {
+		o.MaxInFlightSyncs = n
+	}
+}
+
+// WithPresenceTTL configures the TTL of the presence lease requested from
+// the server on Activate.
+func WithPresenceTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.PresenceTTL = ttl
+	}
+}
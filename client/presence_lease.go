@@ -0,0 +1,129 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+	gotime "time"
+
+	"go.uber.org/zap"
+
+	"github.com/yorkie-team/yorkie/api"
+)
+
+// defaultPresenceTTL is used when Options.PresenceTTL is not set.
+const defaultPresenceTTL = 30 * gotime.Second
+
+// presenceLease tracks the lease the server grants for this client's
+// presence on Activate and keeps it alive with periodic pings, borrowing the
+// lease/keep-alive model etcd's clientv3 uses for its own leases. If the
+// lease is allowed to expire, for example because the process crashed, the
+// server evicts the client and broadcasts a synthesized unwatch event to its
+// peers instead of letting it linger in PeersMapByDoc forever.
+//
+// The ActivatePresenceLease/KeepAlivePresence RPCs and the eviction sweep
+// that expires a lease server-side are server/proto work that lives in the
+// main repository, outside this client-only tree. This file implements the
+// client half against the shape that protocol is expected to have; it
+// cannot make the server grant or enforce a lease on its own.
+type presenceLease struct {
+	mu     sync.RWMutex
+	id     string
+	ttl    gotime.Duration
+	cancel context.CancelFunc
+}
+
+// LeaseID returns the ID of the presence lease currently held by this
+// client, or an empty string if the client has not been activated yet.
+func (c *Client) LeaseID() string {
+	c.lease.mu.RLock()
+	defer c.lease.mu.RUnlock()
+	return c.lease.id
+}
+
+// requestLease asks the server for a new presence lease and starts a
+// background goroutine that keeps it alive at ttl/3 intervals until
+// stopHeartbeat is called or the lease is requested again.
+func (c *Client) requestLease(ctx context.Context) error {
+	ttl := c.lease.ttl
+	if ttl <= 0 {
+		ttl = defaultPresenceTTL
+	}
+
+	res, err := c.client.ActivatePresenceLease(ctx, &api.ActivatePresenceLeaseRequest{
+		ClientId:   c.id.Bytes(),
+		TtlSeconds: int64(ttl.Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+
+	c.startHeartbeat(res.LeaseId, ttl)
+	return nil
+}
+
+// startHeartbeat records the given lease ID and spawns a goroutine that
+// pings KeepAlivePresence every ttl/3 until the client is deactivated or the
+// lease is re-established, for example after Watch reconnects.
+func (c *Client) startHeartbeat(leaseID string, ttl gotime.Duration) {
+	c.stopHeartbeat()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.lease.mu.Lock()
+	c.lease.id = leaseID
+	c.lease.ttl = ttl
+	c.lease.cancel = cancel
+	c.lease.mu.Unlock()
+
+	interval := ttl / 3
+	if interval <= 0 {
+		interval = gotime.Second
+	}
+
+	go func() {
+		ticker := gotime.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := c.client.KeepAlivePresence(ctx, &api.KeepAlivePresenceRequest{
+					ClientId: c.id.Bytes(),
+					LeaseId:  leaseID,
+				}); err != nil {
+					c.logger.Error("failed to keep presence lease alive", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// stopHeartbeat cancels the background keep-alive goroutine, if any.
+func (c *Client) stopHeartbeat() {
+	c.lease.mu.Lock()
+	defer c.lease.mu.Unlock()
+
+	if c.lease.cancel != nil {
+		c.lease.cancel()
+		c.lease.cancel = nil
+	}
+	c.lease.id = ""
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPresenceLease_Heartbeat verifies that startHeartbeat/stopHeartbeat
+// update LeaseID correctly, without waiting for the keep-alive ticker to
+// fire: the ttl used here is long enough that the ticker never ticks during
+// the test, so this never reaches the c.client.KeepAlivePresence call.
+func TestPresenceLease_Heartbeat(t *testing.T) {
+	c := &Client{}
+	assert.Equal(t, "", c.LeaseID())
+
+	c.startHeartbeat("lease-1", time.Hour)
+	assert.Equal(t, "lease-1", c.LeaseID())
+
+	// Re-establishing the lease, for example after Watch reconnects, should
+	// replace the previous heartbeat goroutine rather than leak it.
+	c.startHeartbeat("lease-2", time.Hour)
+	assert.Equal(t, "lease-2", c.LeaseID())
+
+	c.stopHeartbeat()
+	assert.Equal(t, "", c.LeaseID())
+
+	// stopHeartbeat must be safe to call again once already stopped.
+	c.stopHeartbeat()
+	assert.Equal(t, "", c.LeaseID())
+}
@@ -0,0 +1,279 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/yorkie-team/yorkie/api/types"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+// ErrPackNotFound occurs when the requested change pack does not exist in
+// the storage.
+var ErrPackNotFound = errors.New("change pack not found")
+
+// Factory creates a Storage driver from a driver-specific data source name,
+// for example a comma-separated list of etcd endpoints.
+type Factory func(dsn string) (Storage, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Factory)
+)
+
+// Register makes a storage driver available to Open under the given name.
+// It mirrors the driver registry pattern used by database/sql and other
+// CoreOS-era backends (etcd, postgres): a driver self-registers from an
+// init function in the file that implements it, and callers open one by
+// name instead of importing the concrete type. Register panics if called
+// twice with the same name, or with a nil factory.
+func Register(name string, factory Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if factory == nil {
+		panic("client: Register factory is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("client: Register called twice for driver " + name)
+	}
+	drivers[name] = factory
+}
+
+// Open creates a Storage driver previously made available via Register,
+// configuring it with dsn. The returned Storage can be passed to WithStorage.
+func Open(name, dsn string) (Storage, error) {
+	driversMu.RLock()
+	factory, ok := drivers[name]
+	driversMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("client: unknown storage driver %q (forgotten import?)", name)
+	}
+	return factory(dsn)
+}
+
+// Storage is the interface that must be implemented to durably persist the
+// local state of a Client: pending change packs, per-document checkpoints,
+// presence and the actor ID assigned by the server. Drivers are consulted by
+// Activate, Attach, Sync and UpdatePresence so that a crash or restart does
+// not lose unsynced local changes or peer state. Detach does not consult the
+// driver: the pending packs and checkpoint of a detached document are left
+// in storage as they were.
+//
+// Implementations must be safe for concurrent use.
+type Storage interface {
+	// SaveChangePack persists the given change pack as pending for the
+	// document so it can be replayed after a restart.
+	SaveChangePack(ctx context.Context, clientKey string, docKey key.Key, pack *change.Pack) error
+
+	// LoadPendingChangePacks returns the change packs previously saved via
+	// SaveChangePack that have not yet been deleted, ordered by the sequence
+	// they were saved in.
+	LoadPendingChangePacks(ctx context.Context, clientKey string, docKey key.Key) ([]*change.Pack, error)
+
+	// DeletePack removes the pending change pack with the given client
+	// sequence once it has been acknowledged by the server. The client
+	// sequence, not the server sequence, identifies a pending pack: every
+	// pack created before a document's first successful round trip shares
+	// the same (stale) ServerSeq, but ClientSeq is assigned locally and
+	// increases monotonically regardless of server round trips.
+	DeletePack(ctx context.Context, clientKey string, docKey key.Key, clientSeq uint32) error
+
+	// SaveCheckpoint persists the checkpoint of the document.
+	SaveCheckpoint(ctx context.Context, clientKey string, docKey key.Key, cp checkpoint.Checkpoint) error
+
+	// LoadCheckpoint returns the last checkpoint saved for the document, or
+	// the initial checkpoint if none has been saved yet.
+	LoadCheckpoint(ctx context.Context, clientKey string, docKey key.Key) (checkpoint.Checkpoint, error)
+
+	// SavePresence persists the current presence of the client.
+	SavePresence(ctx context.Context, clientKey string, presence types.PresenceInfo) error
+
+	// LoadPresence returns the last presence saved for the client, if any.
+	LoadPresence(ctx context.Context, clientKey string) (types.PresenceInfo, bool, error)
+
+	// SaveActorID persists the actor ID assigned to the client by the server
+	// so that a reconnecting client can resume the same identity.
+	SaveActorID(ctx context.Context, clientKey string, id *time.ActorID) error
+
+	// LoadActorID returns the actor ID previously saved for the client key,
+	// if any.
+	LoadActorID(ctx context.Context, clientKey string) (*time.ActorID, bool, error)
+
+	// Close releases any resources held by the storage driver.
+	Close() error
+}
+
+// docPending holds the pending change packs and checkpoint of a document
+// tracked by the in-memory storage driver.
+type docPending struct {
+	packs []*change.Pack
+	cp    checkpoint.Checkpoint
+}
+
+// MemoryStorage is the default Storage driver. It keeps all state in the
+// process memory, so it behaves exactly like the client did before Storage
+// was introduced: nothing survives a restart.
+type MemoryStorage struct {
+	mu        sync.Mutex
+	docs      map[string]*docPending
+	presences map[string]types.PresenceInfo
+	actorIDs  map[string]*time.ActorID
+}
+
+// NewMemoryStorage creates a new instance of MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		docs:      make(map[string]*docPending),
+		presences: make(map[string]types.PresenceInfo),
+		actorIDs:  make(map[string]*time.ActorID),
+	}
+}
+
+func init() {
+	// The "memory" driver ignores dsn: it has nothing to connect to.
+	Register("memory", func(_ string) (Storage, error) {
+		return NewMemoryStorage(), nil
+	})
+}
+
+func (s *MemoryStorage) pendingOf(clientKey string, docKey key.Key) *docPending {
+	k := clientKey + "/" + docKey.String()
+	pending, ok := s.docs[k]
+	if !ok {
+		pending = &docPending{}
+		s.docs[k] = pending
+	}
+	return pending
+}
+
+// SaveChangePack implements Storage.
+func (s *MemoryStorage) SaveChangePack(_ context.Context, clientKey string, docKey key.Key, pack *change.Pack) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pendingOf(clientKey, docKey)
+	pending.packs = append(pending.packs, pack)
+	return nil
+}
+
+// LoadPendingChangePacks implements Storage.
+func (s *MemoryStorage) LoadPendingChangePacks(
+	_ context.Context,
+	clientKey string,
+	docKey key.Key,
+) ([]*change.Pack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pendingOf(clientKey, docKey)
+	packs := make([]*change.Pack, len(pending.packs))
+	copy(packs, pending.packs)
+	return packs, nil
+}
+
+// DeletePack implements Storage.
+func (s *MemoryStorage) DeletePack(_ context.Context, clientKey string, docKey key.Key, clientSeq uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pendingOf(clientKey, docKey)
+	var remaining []*change.Pack
+	for _, pack := range pending.packs {
+		if pack.Checkpoint.ClientSeq == clientSeq {
+			continue
+		}
+		remaining = append(remaining, pack)
+	}
+	pending.packs = remaining
+	return nil
+}
+
+// SaveCheckpoint implements Storage.
+func (s *MemoryStorage) SaveCheckpoint(
+	_ context.Context,
+	clientKey string,
+	docKey key.Key,
+	cp checkpoint.Checkpoint,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pendingOf(clientKey, docKey).cp = cp
+	return nil
+}
+
+// LoadCheckpoint implements Storage.
+func (s *MemoryStorage) LoadCheckpoint(
+	_ context.Context,
+	clientKey string,
+	docKey key.Key,
+) (checkpoint.Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.pendingOf(clientKey, docKey).cp, nil
+}
+
+// SavePresence implements Storage.
+func (s *MemoryStorage) SavePresence(_ context.Context, clientKey string, presence types.PresenceInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.presences[clientKey] = presence
+	return nil
+}
+
+// LoadPresence implements Storage.
+func (s *MemoryStorage) LoadPresence(_ context.Context, clientKey string) (types.PresenceInfo, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	presence, ok := s.presences[clientKey]
+	return presence, ok, nil
+}
+
+// SaveActorID implements Storage.
+func (s *MemoryStorage) SaveActorID(_ context.Context, clientKey string, id *time.ActorID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.actorIDs[clientKey] = id
+	return nil
+}
+
+// LoadActorID implements Storage.
+func (s *MemoryStorage) LoadActorID(_ context.Context, clientKey string) (*time.ActorID, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.actorIDs[clientKey]
+	return id, ok, nil
+}
+
+// Close implements Storage.
+func (s *MemoryStorage) Close() error {
+	return nil
+}
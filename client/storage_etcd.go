@@ -0,0 +1,288 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/clientv3"
+
+	"github.com/yorkie-team/yorkie/api"
+	"github.com/yorkie-team/yorkie/api/converter"
+	"github.com/yorkie-team/yorkie/api/types"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+// presenceLeaseTTL is the TTL in seconds of the etcd lease that backs a
+// client's presence key. The lease is kept alive for as long as the
+// EtcdStorage is open; if the process dies without a clean Close, the
+// presence key expires on its own instead of lingering forever.
+const presenceLeaseTTL = 30
+
+// EtcdStorage is a Storage driver backed by an etcd v3 cluster. It stores
+// change packs under /yorkie/clients/<clientKey>/docs/<docKey>/changes/<clientSeq>,
+// checkpoints under .../checkpoint, actor IDs under /yorkie/clients/<clientKey>/actor,
+// and presence under a leased key so that it disappears if the client is
+// never closed cleanly. Change packs are keyed by ClientSeq rather than
+// ServerSeq: every pack created before a document's first successful round
+// trip shares the same (stale) ServerSeq, which would collide.
+type EtcdStorage struct {
+	cli     *clientv3.Client
+	leaseID clientv3.LeaseID
+}
+
+// NewEtcdStorage creates a new instance of EtcdStorage that talks to the
+// etcd cluster reachable through the given endpoints.
+func NewEtcdStorage(endpoints []string) (*EtcdStorage, error) {
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints: endpoints,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dial etcd: %w", err)
+	}
+
+	lease, err := cli.Grant(context.Background(), presenceLeaseTTL)
+	if err != nil {
+		if closeErr := cli.Close(); closeErr != nil {
+			return nil, fmt.Errorf("grant lease: %w (close: %v)", err, closeErr)
+		}
+		return nil, fmt.Errorf("grant lease: %w", err)
+	}
+
+	keepAlive, err := cli.KeepAlive(context.Background(), lease.ID)
+	if err != nil {
+		return nil, fmt.Errorf("keep alive lease: %w", err)
+	}
+	go func() {
+		for range keepAlive {
+			// Drain keep-alive responses for the lifetime of the client so
+			// etcd doesn't let the lease expire while we are running.
+		}
+	}()
+
+	return &EtcdStorage{cli: cli, leaseID: lease.ID}, nil
+}
+
+func init() {
+	// The "etcd" driver's dsn is a comma-separated list of endpoints, e.g.
+	// "localhost:2379,localhost:22379".
+	Register("etcd", func(dsn string) (Storage, error) {
+		return NewEtcdStorage(strings.Split(dsn, ","))
+	})
+}
+
+func clientPrefix(clientKey string) string {
+	return "/yorkie/clients/" + clientKey
+}
+
+func changesPrefix(clientKey string, docKey key.Key) string {
+	return fmt.Sprintf("%s/docs/%s/changes/", clientPrefix(clientKey), docKey.String())
+}
+
+func checkpointKey(clientKey string, docKey key.Key) string {
+	return fmt.Sprintf("%s/docs/%s/checkpoint", clientPrefix(clientKey), docKey.String())
+}
+
+func actorKey(clientKey string) string {
+	return clientPrefix(clientKey) + "/actor"
+}
+
+func presenceKey(clientKey string) string {
+	return clientPrefix(clientKey) + "/presence"
+}
+
+// SaveChangePack implements Storage.
+func (s *EtcdStorage) SaveChangePack(ctx context.Context, clientKey string, docKey key.Key, pack *change.Pack) error {
+	pbPack, err := converter.ToChangePack(pack)
+	if err != nil {
+		return err
+	}
+	bytes, err := pbPack.Marshal()
+	if err != nil {
+		return fmt.Errorf("marshal change pack: %w", err)
+	}
+
+	k := changesPrefix(clientKey, docKey) + strconv.FormatUint(uint64(pack.Checkpoint.ClientSeq), 10)
+	if _, err := s.cli.Put(ctx, k, string(bytes)); err != nil {
+		return fmt.Errorf("put change pack: %w", err)
+	}
+	return nil
+}
+
+// LoadPendingChangePacks implements Storage.
+func (s *EtcdStorage) LoadPendingChangePacks(
+	ctx context.Context,
+	clientKey string,
+	docKey key.Key,
+) ([]*change.Pack, error) {
+	resp, err := s.cli.Get(ctx, changesPrefix(clientKey, docKey), clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("get change packs: %w", err)
+	}
+
+	type seqPack struct {
+		seq  int64
+		pack *change.Pack
+	}
+	packs := make([]seqPack, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var pbPack api.ChangePack
+		if err := pbPack.Unmarshal(kv.Value); err != nil {
+			return nil, fmt.Errorf("unmarshal change pack: %w", err)
+		}
+		pack, err := converter.FromChangePack(&pbPack)
+		if err != nil {
+			return nil, err
+		}
+		seq, err := strconv.ParseInt(string(kv.Key[strings.LastIndex(string(kv.Key), "/")+1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse client seq from %q: %w", kv.Key, err)
+		}
+		packs = append(packs, seqPack{seq: seq, pack: pack})
+	}
+
+	sort.Slice(packs, func(i, j int) bool { return packs[i].seq < packs[j].seq })
+
+	result := make([]*change.Pack, len(packs))
+	for i, p := range packs {
+		result[i] = p.pack
+	}
+	return result, nil
+}
+
+// DeletePack implements Storage.
+func (s *EtcdStorage) DeletePack(ctx context.Context, clientKey string, docKey key.Key, clientSeq uint32) error {
+	k := changesPrefix(clientKey, docKey) + strconv.FormatUint(uint64(clientSeq), 10)
+	if _, err := s.cli.Delete(ctx, k); err != nil {
+		return fmt.Errorf("delete change pack: %w", err)
+	}
+	return nil
+}
+
+// SaveCheckpoint implements Storage.
+func (s *EtcdStorage) SaveCheckpoint(
+	ctx context.Context,
+	clientKey string,
+	docKey key.Key,
+	cp checkpoint.Checkpoint,
+) error {
+	v := fmt.Sprintf("%d/%d", cp.ServerSeq, cp.ClientSeq)
+	if _, err := s.cli.Put(ctx, checkpointKey(clientKey, docKey), v); err != nil {
+		return fmt.Errorf("put checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint implements Storage.
+func (s *EtcdStorage) LoadCheckpoint(
+	ctx context.Context,
+	clientKey string,
+	docKey key.Key,
+) (checkpoint.Checkpoint, error) {
+	resp, err := s.cli.Get(ctx, checkpointKey(clientKey, docKey))
+	if err != nil {
+		return checkpoint.Initial, fmt.Errorf("get checkpoint: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return checkpoint.Initial, nil
+	}
+
+	parts := strings.SplitN(string(resp.Kvs[0].Value), "/", 2)
+	serverSeq, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return checkpoint.Initial, fmt.Errorf("parse checkpoint: %w", err)
+	}
+	clientSeq, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return checkpoint.Initial, fmt.Errorf("parse checkpoint: %w", err)
+	}
+
+	return checkpoint.New(serverSeq, uint32(clientSeq)), nil
+}
+
+// SavePresence implements Storage.
+func (s *EtcdStorage) SavePresence(ctx context.Context, clientKey string, presence types.PresenceInfo) error {
+	bytes, err := json.Marshal(presence)
+	if err != nil {
+		return fmt.Errorf("marshal presence: %w", err)
+	}
+
+	if _, err := s.cli.Put(
+		ctx,
+		presenceKey(clientKey),
+		string(bytes),
+		clientv3.WithLease(s.leaseID),
+	); err != nil {
+		return fmt.Errorf("put presence: %w", err)
+	}
+	return nil
+}
+
+// LoadPresence implements Storage.
+func (s *EtcdStorage) LoadPresence(ctx context.Context, clientKey string) (types.PresenceInfo, bool, error) {
+	resp, err := s.cli.Get(ctx, presenceKey(clientKey))
+	if err != nil {
+		return types.PresenceInfo{}, false, fmt.Errorf("get presence: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return types.PresenceInfo{}, false, nil
+	}
+
+	var presence types.PresenceInfo
+	if err := json.Unmarshal(resp.Kvs[0].Value, &presence); err != nil {
+		return types.PresenceInfo{}, false, fmt.Errorf("unmarshal presence: %w", err)
+	}
+	return presence, true, nil
+}
+
+// SaveActorID implements Storage.
+func (s *EtcdStorage) SaveActorID(ctx context.Context, clientKey string, id *time.ActorID) error {
+	if _, err := s.cli.Put(ctx, actorKey(clientKey), string(id.Bytes())); err != nil {
+		return fmt.Errorf("put actor id: %w", err)
+	}
+	return nil
+}
+
+// LoadActorID implements Storage.
+func (s *EtcdStorage) LoadActorID(ctx context.Context, clientKey string) (*time.ActorID, bool, error) {
+	resp, err := s.cli.Get(ctx, actorKey(clientKey))
+	if err != nil {
+		return nil, false, fmt.Errorf("get actor id: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, false, nil
+	}
+
+	id, err := time.ActorIDFromBytes(resp.Kvs[0].Value)
+	if err != nil {
+		return nil, false, err
+	}
+	return id, true, nil
+}
+
+// Close implements Storage.
+func (s *EtcdStorage) Close() error {
+	return s.cli.Close()
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorkie-team/yorkie/client"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/checkpoint"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+	"github.com/yorkie-team/yorkie/pkg/document/time"
+)
+
+// TestMemoryStorage_Restart simulates a crash by saving change packs and
+// never deleting them, then re-reading the storage as a fresh client would
+// on restart to make sure nothing saved before the crash is lost.
+func TestMemoryStorage_Restart(t *testing.T) {
+	ctx := context.Background()
+	storage := client.NewMemoryStorage()
+	clientKey := "client-1"
+	docKey := key.Key("doc-1")
+
+	packA := change.NewPack(docKey.String(), checkpoint.New(0, 1), nil, nil)
+	packB := change.NewPack(docKey.String(), checkpoint.New(0, 2), nil, nil)
+
+	require.NoError(t, storage.SaveChangePack(ctx, clientKey, docKey, packA))
+	require.NoError(t, storage.SaveChangePack(ctx, clientKey, docKey, packB))
+
+	// The process "restarts" here: a new Client would call
+	// LoadPendingChangePacks to find out what was never acknowledged.
+	pending, err := storage.LoadPendingChangePacks(ctx, clientKey, docKey)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, packA.Checkpoint.ClientSeq, pending[0].Checkpoint.ClientSeq)
+	assert.Equal(t, packB.Checkpoint.ClientSeq, pending[1].Checkpoint.ClientSeq)
+
+	// packA and packB share the same ServerSeq because neither has ever
+	// completed a round trip yet; ClientSeq is what tells them apart. Once
+	// the server acknowledges packA, only packA is removed, and packB
+	// remains pending for the next replay attempt.
+	require.NoError(t, storage.DeletePack(ctx, clientKey, docKey, packA.Checkpoint.ClientSeq))
+
+	pending, err = storage.LoadPendingChangePacks(ctx, clientKey, docKey)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, packB.Checkpoint.ClientSeq, pending[0].Checkpoint.ClientSeq)
+}
+
+// TestMemoryStorage_Checkpoint verifies that a document's checkpoint is
+// durable across the LoadCheckpoint call a restarted client makes in Attach.
+func TestMemoryStorage_Checkpoint(t *testing.T) {
+	ctx := context.Background()
+	storage := client.NewMemoryStorage()
+	clientKey := "client-1"
+	docKey := key.Key("doc-1")
+
+	cp, err := storage.LoadCheckpoint(ctx, clientKey, docKey)
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint.Initial, cp)
+
+	want := checkpoint.New(5, 3)
+	require.NoError(t, storage.SaveCheckpoint(ctx, clientKey, docKey, want))
+
+	got, err := storage.LoadCheckpoint(ctx, clientKey, docKey)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+// TestOpen_UnknownDriver verifies that Open reports an actionable error for
+// a driver name nothing has registered.
+func TestOpen_UnknownDriver(t *testing.T) {
+	_, err := client.Open("no-such-driver", "")
+	assert.Error(t, err)
+}
+
+// TestOpen_Memory verifies that the "memory" driver registered by this
+// package's init function is reachable through the same Open entry point
+// used for out-of-tree drivers.
+func TestOpen_Memory(t *testing.T) {
+	storage, err := client.Open("memory", "")
+	require.NoError(t, err)
+	require.NotNil(t, storage)
+	assert.NoError(t, storage.Close())
+}
+
+// TestMemoryStorage_ActorID verifies that a reconnecting client can find the
+// actor ID it was assigned before, so it can offer it back to the server as
+// a hint on the next Activate.
+func TestMemoryStorage_ActorID(t *testing.T) {
+	ctx := context.Background()
+	storage := client.NewMemoryStorage()
+	clientKey := "client-1"
+
+	_, ok, err := storage.LoadActorID(ctx, clientKey)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	id, err := time.ActorIDFromBytes(make([]byte, 12))
+	require.NoError(t, err)
+	require.NoError(t, storage.SaveActorID(ctx, clientKey, id))
+
+	got, ok, err := storage.LoadActorID(ctx, clientKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, id, got)
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2020 The Yorkie Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorkie-team/yorkie/pkg/document"
+	"github.com/yorkie-team/yorkie/pkg/document/change"
+	"github.com/yorkie-team/yorkie/pkg/document/key"
+)
+
+// failingStorage fails every SaveChangePack call, standing in for a backend
+// that is briefly unreachable when a batch's other documents save fine.
+type failingStorage struct {
+	*MemoryStorage
+}
+
+func (s *failingStorage) SaveChangePack(_ context.Context, _ string, _ key.Key, _ *change.Pack) error {
+	return errors.New("storage unavailable")
+}
+
+// TestClient_prepareSyncFrame_PartialFailureBatch exercises the scenario
+// syncStream's sender loop has to cope with on every Sync call spanning
+// multiple documents: some keys fail before a frame is ever sent (not
+// attached, storage error) while others succeed, and the receive goroutine
+// must only ever wait for frames that actually went out. prepareSyncFrame is
+// where that local pass/fail decision is made, so it can be tested directly
+// without a fake api.YorkieClient stream.
+func TestClient_prepareSyncFrame_PartialFailureBatch(t *testing.T) {
+	ctx := context.Background()
+	attachedKey := key.Key("doc-attached")
+	unattachedKey := key.Key("doc-unattached")
+
+	c := &Client{
+		key:     "client-1",
+		storage: NewMemoryStorage(),
+		attachments: map[string]*Attachment{
+			attachedKey.String(): {doc: document.New(attachedKey)},
+		},
+	}
+
+	// A key with no attachment fails immediately, before any storage or
+	// network work is attempted.
+	_, _, err := c.prepareSyncFrame(ctx, unattachedKey)
+	assert.ErrorIs(t, err, ErrDocumentNotAttached)
+
+	// An attached key with healthy storage succeeds and produces a frame
+	// ready to send.
+	localPack, pbChangePack, err := c.prepareSyncFrame(ctx, attachedKey)
+	require.NoError(t, err)
+	assert.NotNil(t, localPack)
+	assert.NotNil(t, pbChangePack)
+
+	// An attached key whose storage fails to save the pack also fails
+	// locally, never reaching the point of sending a frame.
+	c.storage = &failingStorage{MemoryStorage: NewMemoryStorage()}
+	_, _, err = c.prepareSyncFrame(ctx, attachedKey)
+	assert.Error(t, err)
+}